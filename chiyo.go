@@ -2,7 +2,9 @@ package chiyo
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 )
 
@@ -14,10 +16,14 @@ type (
 	}
 
 	Router struct {
-		staticRoutes  map[string]http.HandlerFunc
-		dynamicRoutes map[string]*node
-		middleware    []func(http.HandlerFunc) http.HandlerFunc
-		notFound      http.HandlerFunc
+		staticRoutes     map[string]http.HandlerFunc
+		dynamicRoutes    map[string]*node
+		methods          map[string]struct{}
+		middleware       []func(http.HandlerFunc) http.HandlerFunc
+		routeMiddleware  []func(http.HandlerFunc) http.HandlerFunc
+		notFound         http.HandlerFunc
+		methodNotAllowed http.HandlerFunc
+		handleOptions    bool
 	}
 
 	Group struct {
@@ -27,23 +33,68 @@ type (
 	}
 )
 
-type node struct {
-	children   map[string]*node
-	handler    http.HandlerFunc
-	isParam    bool
-	isWillcard bool
-	paramName  string
+// joinPath joins path segments with "/", trimming each and dropping any
+// that are empty so an empty prefix (e.g. from Group("")) never introduces
+// a double slash into the result.
+func joinPath(parts ...string) string {
+	nonEmpty := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.Trim(p, "/"); p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, "/")
 }
 
 func NewRouter() *Router {
 	return &Router{
-		staticRoutes:  make(map[string]http.HandlerFunc),
-		dynamicRoutes: make(map[string]*node),
-		middleware:    []func(http.HandlerFunc) http.HandlerFunc{},
-		notFound:      http.NotFound,
+		staticRoutes:     make(map[string]http.HandlerFunc),
+		dynamicRoutes:    make(map[string]*node),
+		methods:          make(map[string]struct{}),
+		middleware:       []func(http.HandlerFunc) http.HandlerFunc{},
+		notFound:         http.NotFound,
+		methodNotAllowed: defaultMethodNotAllowed,
+		handleOptions:    true,
 	}
 }
 
+// defaultMethodNotAllowed writes a bare 405; the Allow header is already
+// set by ServeHTTP before this runs.
+func defaultMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+// MethodNotAllowed overrides the handler ServeHTTP dispatches when a
+// request matches a registered path but not its method. The Allow header
+// is set before h runs, so h only needs to decide on status and body.
+func (r *Router) MethodNotAllowed(h http.HandlerFunc) {
+	r.methodNotAllowed = h
+}
+
+// HandleOptions toggles chiyo's automatic OPTIONS handling. When enabled
+// (the default), an OPTIONS request to any path with at least one
+// registered method - and no explicit OPTIONS route of its own - gets a
+// 204 with an Allow header instead of falling through to 405/404.
+func (r *Router) HandleOptions(enabled bool) {
+	r.handleOptions = enabled
+}
+
+// With returns a shallow view of r that carries mw in addition to any
+// middleware already accumulated by a previous With call, so a single
+// route can be wrapped with extra middleware without opening a Group. The
+// view shares r's route tables, so AddRoute calls on it (directly, or via
+// Group/Mount built from it) register into the same router; only the
+// middleware wrapping applied at registration time differs.
+func (r *Router) With(mw ...func(http.HandlerFunc) http.HandlerFunc) *Router {
+	combined := make([]func(http.HandlerFunc) http.HandlerFunc, len(r.routeMiddleware)+len(mw))
+	copy(combined, r.routeMiddleware)
+	copy(combined[len(r.routeMiddleware):], mw)
+
+	view := *r
+	view.routeMiddleware = combined
+	return &view
+}
+
 func (r *Router) Group(prefix string) *Group {
 	return &Group{
 		prefix: strings.Trim(prefix, "/"),
@@ -51,32 +102,56 @@ func (r *Router) Group(prefix string) *Group {
 	}
 }
 
-func (r *Router) AddRoute(method, path string, handler http.HandlerFunc) {
+// Group returns a sub-group nested under g, combining the two prefixes and
+// inheriting g's middleware. g's middleware slice is copied rather than
+// shared, so later calls to g.Use do not retroactively apply to routes
+// already registered on the child (or vice versa).
+func (g *Group) Group(prefix string) *Group {
+	inherited := make([]func(http.HandlerFunc) http.HandlerFunc, len(g.middleware))
+	copy(inherited, g.middleware)
+
+	return &Group{
+		prefix:     joinPath(g.prefix, prefix),
+		middleware: inherited,
+		router:     g.router,
+	}
+}
+
+func (r *Router) AddRoute(method, path string, handler http.HandlerFunc) error {
 	path = strings.Trim(path, "/")
-	parts := strings.Split(path, "/")
+	r.methods[method] = struct{}{}
+
+	for i := len(r.routeMiddleware) - 1; i >= 0; i-- {
+		handler = r.routeMiddleware[i](handler)
+	}
 
 	if strings.Contains(path, ":") || strings.Contains(path, "*") {
 		if r.dynamicRoutes[method] == nil {
-			r.dynamicRoutes[method] = &node{
-				children: make(map[string]*node),
-			}
+			r.dynamicRoutes[method] = &node{}
 		}
 
-		r.insertDynamicRoute(method, parts, handler)
-	} else {
-		r.staticRoutes[method+" "+path] = handler
+		parts := strings.Split(path, "/")
+		return r.dynamicRoutes[method].insert(parts, path, handler)
 	}
+
+	key := method + " " + path
+	if _, exists := r.staticRoutes[key]; exists {
+		return fmt.Errorf("chiyo: duplicate route for %s %s", method, path)
+	}
+
+	r.staticRoutes[key] = handler
+	return nil
 }
 
-func (g *Group) AddRoute(method, path string, handler http.HandlerFunc) {
-	fullPath := g.prefix + "/" + strings.Trim(path, "/")
+func (g *Group) AddRoute(method, path string, handler http.HandlerFunc) error {
+	fullPath := joinPath(g.prefix, path)
 	wrappedHandler := handler
 
-	for i := len(g.middleware); i >= 0; i-- {
+	for i := len(g.middleware) - 1; i >= 0; i-- {
 		wrappedHandler = g.middleware[i](wrappedHandler)
 	}
 
-	g.router.AddRoute(method, fullPath, wrappedHandler)
+	return g.router.AddRoute(method, fullPath, wrappedHandler)
 }
 
 func (r *Router) Use(mw func(http.HandlerFunc) http.HandlerFunc) {
@@ -87,61 +162,81 @@ func (g *Group) Use(mw func(http.HandlerFunc) http.HandlerFunc) {
 	g.middleware = append(g.middleware, mw)
 }
 
-func (r *Router) insertDynamicRoute(method string, parts []string, handler http.HandlerFunc) {
-	current := r.dynamicRoutes[method]
+// mountMethods lists the methods a Mount registers its sub-router under, so
+// it answers any of them whether or not sub registers that method itself.
+// CONNECT and TRACE are not among them.
+var mountMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodHead, http.MethodOptions,
+}
 
-	for _, part := range parts {
-		var key string
-		var isParam, isWillcard bool
-		var paramName string
+// mountHandler returns a handler that rewrites req.URL.Path to whatever
+// sub-router matched after pattern and delegates the rest of the request to
+// sub, so sub routes against a path relative to the mount point rather than
+// the full incoming one.
+func mountHandler(sub *Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		req2 := req.Clone(req.Context())
+		req2.URL.Path = "/" + URLParam(req, "*")
+		sub.ServeHTTP(w, req2)
+	}
+}
 
-		if strings.HasPrefix(part, ":") {
-			key = ":param"
-			isParam = true
-			paramName = strings.TrimPrefix(part, ":")
-		} else if strings.HasPrefix(part, "*") {
-			key = "*"
-			isWillcard = true
-		} else {
-			key = part
+// Mount attaches sub under pattern, so any request whose path starts with
+// pattern is routed by sub instead, against the remainder of the path. It
+// registers both the bare prefix (for sub's own root route) and a
+// catch-all for everything below it, per HTTP method, since mounted
+// traffic isn't limited to whatever methods sub happens to use.
+func (r *Router) Mount(pattern string, sub *Router) error {
+	prefix := strings.Trim(pattern, "/")
+	handler := mountHandler(sub)
+
+	for _, method := range mountMethods {
+		if err := r.AddRoute(method, prefix, handler); err != nil {
+			return err
 		}
-
-		if _, exists := current.children[key]; !exists {
-			current.children[key] = &node{
-				children:   make(map[string]*node),
-				isParam:    isParam,
-				isWillcard: isWillcard,
-				paramName:  paramName,
-			}
+		if err := r.AddRoute(method, prefix+"/*", handler); err != nil {
+			return err
 		}
+	}
 
-		current = current.children[key]
+	return nil
+}
+
+// Mount attaches sub under pattern within g, applying g's middleware and
+// prefix the same way AddRoute does.
+func (g *Group) Mount(pattern string, sub *Router) error {
+	prefix := joinPath(g.prefix, pattern)
+	wrappedHandler := mountHandler(sub)
+
+	for i := len(g.middleware) - 1; i >= 0; i-- {
+		wrappedHandler = g.middleware[i](wrappedHandler)
+	}
+
+	for _, method := range mountMethods {
+		if err := g.router.AddRoute(method, prefix, wrappedHandler); err != nil {
+			return err
+		}
+		if err := g.router.AddRoute(method, prefix+"/*", wrappedHandler); err != nil {
+			return err
+		}
 	}
 
-	current.handler = handler
+	return nil
 }
 
-func (r *Router) searchDynamicRoute(root *node, path string) (http.HandlerFunc, map[string]string) {
+// searchDynamicRoute walks path against root and reports the handler and
+// registered pattern of the first match, along with the params captured
+// along the way.
+func (r *Router) searchDynamicRoute(root *node, path string) (http.HandlerFunc, routeParams, string) {
 	parts := strings.Split(path, "/")
-	current := root
-	params := make(map[string]string)
-
-	for _, part := range parts {
-		if child, exists := current.children[part]; exists {
-			current = child
-		} else if paramChild, exists := current.children[":param"]; exists {
-			current = paramChild
-			if current.paramName != "" {
-				params[current.paramName] = part
-			}
-		} else if wildcardChild, exists := current.children["*"]; exists {
-			return wildcardChild.handler, params
-		} else {
-			return nil, nil
-		}
+	var params routeParams
+
+	if m := root.search(parts, &params); m != nil {
+		return m.handler, params, m.pattern
 	}
 
-	return current.handler, params
+	return nil, params, ""
 }
 
 func (r *Router) serveWithMiddleware(handler http.HandlerFunc, w http.ResponseWriter, req *http.Request) {
@@ -157,25 +252,105 @@ func (r *Router) serveWithMiddleware(handler http.HandlerFunc, w http.ResponseWr
 	handler(w, req)
 }
 
+// match looks up the handler registered for method and path, trying the
+// static routes before falling back to the method's dynamic tree. The
+// returned pattern is the route template as registered (e.g. "users/:id"),
+// not the literal request path, so callers can use it for low-cardinality
+// labeling.
+func (r *Router) match(method, path string) (http.HandlerFunc, routeParams, string, bool) {
+	if handler, exists := r.staticRoutes[method+" "+path]; exists {
+		return handler, routeParams{}, path, true
+	}
+
+	if root, exists := r.dynamicRoutes[method]; exists {
+		if handler, params, pattern := r.searchDynamicRoute(root, path); handler != nil {
+			return handler, params, pattern, true
+		}
+	}
+
+	return nil, routeParams{}, "", false
+}
+
+// allowedMethods reports, in sorted order, every method that would
+// successfully match path - every method with its own registration, plus
+// HEAD when GET is registered and OPTIONS whenever any method matches and
+// r.handleOptions is on. It is used to answer automatic OPTIONS requests
+// and to populate the Allow header on a 405.
+func (r *Router) allowedMethods(path string) []string {
+	set := make(map[string]struct{})
+
+	for method := range r.methods {
+		if _, _, _, ok := r.match(method, path); ok {
+			set[method] = struct{}{}
+		}
+	}
+
+	if _, ok := set[http.MethodGet]; ok {
+		set[http.MethodHead] = struct{}{}
+	}
+
+	if len(set) == 0 {
+		return nil
+	}
+	if r.handleOptions {
+		set[http.MethodOptions] = struct{}{}
+	}
+
+	methods := make([]string, 0, len(set))
+	for method := range set {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	return methods
+}
+
+// headResponseWriter discards a response body so the automatic HEAD
+// fallback can run the matching GET handler without leaking one.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	path := strings.Trim(req.URL.Path, "/")
 	method := req.Method
-	fullPath := method + " " + path
 
-	if handler, exists := r.staticRoutes[fullPath]; exists {
+	if handler, params, pattern, ok := r.match(method, path); ok {
+		rctx := &Context{routePattern: method + " " + pattern, params: params}
+		req = req.WithContext(context.WithValue(req.Context(), routeCtxKey, rctx))
+
 		r.serveWithMiddleware(handler, w, req)
 		return
 	}
 
-	if root, exists := r.dynamicRoutes[method]; exists {
-		if handler, params := r.searchDynamicRoute(root, path); handler != nil {
-			ctx := context.WithValue(req.Context(), "params", params)
-			req = req.WithContext(ctx)
+	if method == http.MethodHead {
+		if handler, params, pattern, ok := r.match(http.MethodGet, path); ok {
+			rctx := &Context{routePattern: http.MethodGet + " " + pattern, params: params}
+			req = req.WithContext(context.WithValue(req.Context(), routeCtxKey, rctx))
 
-			r.serveWithMiddleware(handler, w, req)
+			r.serveWithMiddleware(func(w http.ResponseWriter, req *http.Request) {
+				handler(&headResponseWriter{ResponseWriter: w}, req)
+			}, w, req)
 			return
 		}
 	}
 
-	r.notFound(w, req)
+	allowed := r.allowedMethods(path)
+	if len(allowed) == 0 {
+		r.notFound(w, req)
+		return
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+
+	if method == http.MethodOptions && r.handleOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	r.methodNotAllowed(w, req)
 }
@@ -0,0 +1,92 @@
+package chiyo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newUsersRouter(t *testing.T) *Router {
+	t.Helper()
+
+	r := NewRouter()
+	err := r.AddRoute(http.MethodGet, "/users/:id", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("user " + URLParam(req, "id")))
+	})
+	if err != nil {
+		t.Fatalf("AddRoute: %v", err)
+	}
+
+	return r
+}
+
+func TestServeHTTPMethodMatching(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantAllow  string
+		wantBody   string
+	}{
+		{"matched GET", http.MethodGet, "/users/42", http.StatusOK, "", "user 42"},
+		{"unregistered method on a known path gets 405", http.MethodPost, "/users/42", http.StatusMethodNotAllowed, "GET, HEAD, OPTIONS", ""},
+		{"automatic OPTIONS", http.MethodOptions, "/users/42", http.StatusNoContent, "GET, HEAD, OPTIONS", ""},
+		{"automatic HEAD falls back to GET", http.MethodHead, "/users/42", http.StatusOK, "", ""},
+		{"unregistered path is a 404", http.MethodGet, "/nope", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newUsersRouter(t)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantAllow != "" {
+				if got := w.Header().Get("Allow"); got != tt.wantAllow {
+					t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+				}
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if tt.method == http.MethodHead && w.Body.Len() != 0 {
+				t.Errorf("HEAD response body = %q, want empty", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouterMethodNotAllowedOverride(t *testing.T) {
+	r := newUsersRouter(t)
+	r.MethodNotAllowed(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/42", nil))
+
+	if w.Code != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusTeapot)
+	}
+}
+
+func TestRouterHandleOptionsDisabled(t *testing.T) {
+	r := newUsersRouter(t)
+	r.HandleOptions(false)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/users/42", nil))
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+	if got := w.Header().Get("Allow"); got != "GET, HEAD" {
+		t.Errorf("Allow = %q, want %q (OPTIONS should not be advertised once disabled)", got, "GET, HEAD")
+	}
+}
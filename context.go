@@ -0,0 +1,126 @@
+package chiyo
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// paramsInlineCap is the number of URL params stored inline before routeParams
+// falls back to a growable slice. Most routes match within this budget, so the
+// common case never allocates.
+const paramsInlineCap = 8
+
+// routeParams is a small fixed-capacity store of URL parameter key/value pairs,
+// backed by parallel arrays instead of a map so lookups stay allocation-free
+// for the typical route.
+type routeParams struct {
+	keys   [paramsInlineCap]string
+	values [paramsInlineCap]string
+	n      int
+
+	extraKeys   []string
+	extraValues []string
+}
+
+func (p *routeParams) add(key, value string) {
+	if p.n < paramsInlineCap {
+		p.keys[p.n] = key
+		p.values[p.n] = value
+		p.n++
+		return
+	}
+
+	p.extraKeys = append(p.extraKeys, key)
+	p.extraValues = append(p.extraValues, value)
+}
+
+// mark returns a snapshot of p's current length, to be passed to truncate to
+// undo any params added after the mark.
+func (p *routeParams) mark() (n, extraN int) {
+	return p.n, len(p.extraKeys)
+}
+
+func (p *routeParams) truncate(n, extraN int) {
+	p.n = n
+	p.extraKeys = p.extraKeys[:extraN]
+	p.extraValues = p.extraValues[:extraN]
+}
+
+func (p *routeParams) get(key string) string {
+	for i := 0; i < p.n; i++ {
+		if p.keys[i] == key {
+			return p.values[i]
+		}
+	}
+
+	for i, k := range p.extraKeys {
+		if k == key {
+			return p.extraValues[i]
+		}
+	}
+
+	return ""
+}
+
+// contextKey is an unexported type used as a context.Context key so chiyo's
+// values can never collide with keys set by other packages.
+type contextKey struct {
+	name string
+}
+
+func (k *contextKey) String() string {
+	return "chiyo/" + k.name
+}
+
+var routeCtxKey = &contextKey{"RouteContext"}
+
+// Context carries the per-request routing information chiyo resolves while
+// matching a path: the URL params captured along the way and the pattern that
+// was matched.
+type Context struct {
+	routePattern string
+	params       routeParams
+}
+
+// RoutePattern returns the pattern that matched the current request, e.g.
+// "GET users/:id". It is empty if no route matched.
+func (c *Context) RoutePattern() string {
+	return c.routePattern
+}
+
+// URLParam returns the value of the URL param with the given name, or "" if
+// it was not captured for the current route.
+func (c *Context) URLParam(key string) string {
+	return c.params.get(key)
+}
+
+// RouteContext returns the chiyo *Context stashed on req by the router, or
+// nil if req was not served through a chiyo Router.
+func RouteContext(r *http.Request) *Context {
+	rctx, _ := r.Context().Value(routeCtxKey).(*Context)
+	return rctx
+}
+
+// URLParam returns the value of the URL param with the given name for the
+// current request, or "" if it isn't present.
+func URLParam(r *http.Request, name string) string {
+	if rctx := RouteContext(r); rctx != nil {
+		return rctx.URLParam(name)
+	}
+	return ""
+}
+
+// URLParamInt returns the URL param with the given name parsed as an int.
+func URLParamInt(r *http.Request, name string) (int, error) {
+	return strconv.Atoi(URLParam(r, name))
+}
+
+// URLParamInt64 returns the URL param with the given name parsed as an int64.
+func URLParamInt64(r *http.Request, name string) (int64, error) {
+	return strconv.ParseInt(URLParam(r, name), 10, 64)
+}
+
+// URLParamBool returns the URL param with the given name parsed as a bool.
+func URLParamBool(r *http.Request, name string) (bool, error) {
+	return strconv.ParseBool(URLParam(r, name))
+}
@@ -0,0 +1,148 @@
+package chiyo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestURLParam(t *testing.T) {
+	r := NewRouter()
+	err := r.AddRoute(http.MethodGet, "/users/:id", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(URLParam(req, "id")))
+	})
+	if err != nil {
+		t.Fatalf("AddRoute: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	if w.Body.String() != "42" {
+		t.Errorf("body = %q, want %q", w.Body.String(), "42")
+	}
+
+	if got := URLParam(httptest.NewRequest(http.MethodGet, "/users/42", nil), "id"); got != "" {
+		t.Errorf("URLParam on a request never served through a Router = %q, want %q", got, "")
+	}
+}
+
+func TestURLParamInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"valid", "42", 42, false},
+		{"not a number", "abc", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRouter()
+			if err := r.AddRoute(http.MethodGet, "/n/:n", func(w http.ResponseWriter, req *http.Request) {
+				got, err := URLParamInt(req, "n")
+				if (err != nil) != tt.wantErr {
+					t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+				}
+				if !tt.wantErr && got != tt.want {
+					t.Errorf("URLParamInt = %d, want %d", got, tt.want)
+				}
+			}); err != nil {
+				t.Fatalf("AddRoute: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/n/"+tt.value, nil))
+		})
+	}
+}
+
+func TestURLParamInt64(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"valid", "9000000000", 9000000000, false},
+		{"not a number", "abc", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRouter()
+			if err := r.AddRoute(http.MethodGet, "/n/:n", func(w http.ResponseWriter, req *http.Request) {
+				got, err := URLParamInt64(req, "n")
+				if (err != nil) != tt.wantErr {
+					t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+				}
+				if !tt.wantErr && got != tt.want {
+					t.Errorf("URLParamInt64 = %d, want %d", got, tt.want)
+				}
+			}); err != nil {
+				t.Fatalf("AddRoute: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/n/"+tt.value, nil))
+		})
+	}
+}
+
+func TestURLParamBool(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{"true", "true", true, false},
+		{"false", "false", false, false},
+		{"not a bool", "nope", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRouter()
+			if err := r.AddRoute(http.MethodGet, "/b/:b", func(w http.ResponseWriter, req *http.Request) {
+				got, err := URLParamBool(req, "b")
+				if (err != nil) != tt.wantErr {
+					t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+				}
+				if !tt.wantErr && got != tt.want {
+					t.Errorf("URLParamBool = %v, want %v", got, tt.want)
+				}
+			}); err != nil {
+				t.Fatalf("AddRoute: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/b/"+tt.value, nil))
+		})
+	}
+}
+
+func TestRoutePattern(t *testing.T) {
+	r := NewRouter()
+	err := r.AddRoute(http.MethodGet, "/users/:id", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(RouteContext(req).RoutePattern()))
+	})
+	if err != nil {
+		t.Fatalf("AddRoute: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	if want := "GET users/:id"; w.Body.String() != want {
+		t.Errorf("RoutePattern() = %q, want %q", w.Body.String(), want)
+	}
+}
+
+func TestRouteContextNilOutsideRouter(t *testing.T) {
+	if rctx := RouteContext(httptest.NewRequest(http.MethodGet, "/", nil)); rctx != nil {
+		t.Errorf("RouteContext = %v, want nil", rctx)
+	}
+}
@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Compress returns middleware that encodes response bodies with gzip when
+// the client's Accept-Encoding mentions gzip, and with deflate when it only
+// mentions deflate (the header's order and q-values are not consulted),
+// at the given compression level (see compress/gzip for valid values;
+// gzip.DefaultCompression is a reasonable default). A response is only
+// compressed once its body reaches minLength bytes and, if types is
+// non-empty, its Content-Type matches one of them; smaller or unlisted
+// responses pass through untouched so compression overhead isn't spent
+// where it won't pay off.
+func Compress(level, minLength int, types ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" {
+				next(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				level:          level,
+				minLength:      minLength,
+				types:          types,
+				encoding:       encoding,
+			}
+			defer cw.Close()
+
+			next(cw, r)
+		}
+	}
+}
+
+// negotiateEncoding picks gzip over deflate when a client's Accept-Encoding
+// advertises both, and returns "" if neither is present.
+func negotiateEncoding(acceptEncoding string) string {
+	switch {
+	case strings.Contains(acceptEncoding, "gzip"):
+		return "gzip"
+	case strings.Contains(acceptEncoding, "deflate"):
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+// compressWriter buffers the response body until it either reaches
+// minLength or the handler finishes, at which point it decides once
+// whether to compress - based on the buffered size and, if types is set,
+// the Content-Type the handler settled on - and writes everything seen so
+// far through the chosen path. Every write after that decision goes
+// straight through the same path.
+type compressWriter struct {
+	http.ResponseWriter
+	level     int
+	minLength int
+	types     []string
+	encoding  string
+
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+	decided     bool
+	enc         io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf.Write(b)
+	if w.buf.Len() < w.minLength {
+		return len(b), nil
+	}
+
+	if err := w.decide(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// decide finalizes whether to compress, exactly once, and flushes
+// whatever has been buffered so far through the result. It is also called
+// from Close, so a body that never reached minLength still has to pass
+// the size check here, not just rely on Write never having triggered it.
+func (w *compressWriter) decide() error {
+	if w.decided {
+		return nil
+	}
+	w.decided = true
+
+	if w.buf.Len() >= w.minLength && w.typeAllowed() {
+		w.Header().Set("Content-Encoding", w.encoding)
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.Header().Del("Content-Length")
+		w.flushStatus()
+
+		enc, err := w.newEncoder()
+		if err != nil {
+			w.enc = nil
+			_, err = w.ResponseWriter.Write(w.buf.Bytes())
+			return err
+		}
+		w.enc = enc
+		_, err = w.enc.Write(w.buf.Bytes())
+		return err
+	}
+
+	w.flushStatus()
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	return err
+}
+
+func (w *compressWriter) newEncoder() (io.WriteCloser, error) {
+	switch w.encoding {
+	case "gzip":
+		return gzip.NewWriterLevel(w.ResponseWriter, w.level)
+	case "deflate":
+		return flate.NewWriter(w.ResponseWriter, w.level)
+	default:
+		return nil, nil
+	}
+}
+
+func (w *compressWriter) flushStatus() {
+	if w.wroteHeader {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+// typeAllowed reports whether the response's Content-Type (ignoring any
+// parameters like charset) is in w.types, or whether w.types is empty and
+// every type is allowed.
+func (w *compressWriter) typeAllowed() bool {
+	if len(w.types) == 0 {
+		return true
+	}
+
+	ct := w.Header().Get("Content-Type")
+	if i := strings.IndexByte(ct, ';'); i >= 0 {
+		ct = ct[:i]
+	}
+	ct = strings.TrimSpace(ct)
+
+	for _, t := range w.types {
+		if strings.EqualFold(ct, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Close finalizes the compression decision if the response never reached
+// minLength, and closes the underlying encoder so it flushes its trailer.
+func (w *compressWriter) Close() error {
+	if err := w.decide(); err != nil {
+		return err
+	}
+	if w.enc != nil {
+		return w.enc.Close()
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func decodeBody(t *testing.T, body []byte, encoding string) string {
+	t.Helper()
+
+	switch encoding {
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("gzip.NewReader: %v", err)
+		}
+		defer zr.Close()
+		out, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("reading gzip body: %v", err)
+		}
+		return string(out)
+	case "deflate":
+		fr := flate.NewReader(bytes.NewReader(body))
+		defer fr.Close()
+		out, err := io.ReadAll(fr)
+		if err != nil {
+			t.Fatalf("reading deflate body: %v", err)
+		}
+		return string(out)
+	default:
+		return string(body)
+	}
+}
+
+func TestCompressDecision(t *testing.T) {
+	const longBody = "a response body long enough to clear any small test threshold"
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		minLength      int
+		types          []string
+		contentType    string
+		body           string
+		wantEncoding   string
+	}{
+		{
+			name:           "below the threshold stays uncompressed",
+			acceptEncoding: "gzip",
+			minLength:      1024,
+			contentType:    "text/plain",
+			body:           "short",
+			wantEncoding:   "",
+		},
+		{
+			name:           "above the threshold prefers gzip when both are offered",
+			acceptEncoding: "gzip, deflate",
+			minLength:      4,
+			contentType:    "text/plain",
+			body:           longBody,
+			wantEncoding:   "gzip",
+		},
+		{
+			name:           "deflate is used when gzip isn't offered",
+			acceptEncoding: "deflate",
+			minLength:      4,
+			contentType:    "text/plain",
+			body:           longBody,
+			wantEncoding:   "deflate",
+		},
+		{
+			name:           "content-type outside the allowlist stays uncompressed",
+			acceptEncoding: "gzip",
+			minLength:      4,
+			types:          []string{"application/json"},
+			contentType:    "text/plain",
+			body:           longBody,
+			wantEncoding:   "",
+		},
+		{
+			name:           "content-type inside the allowlist compresses",
+			acceptEncoding: "gzip",
+			minLength:      4,
+			types:          []string{"application/json"},
+			contentType:    "application/json",
+			body:           longBody,
+			wantEncoding:   "gzip",
+		},
+		{
+			name:           "no acceptable Accept-Encoding passes through untouched",
+			acceptEncoding: "br",
+			minLength:      0,
+			contentType:    "text/plain",
+			body:           longBody,
+			wantEncoding:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Compress(gzip.DefaultCompression, tt.minLength, tt.types...)(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write([]byte(tt.body))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			rec := httptest.NewRecorder()
+
+			handler(rec, req)
+
+			if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+
+			if got := decodeBody(t, rec.Body.Bytes(), tt.wantEncoding); got != tt.body {
+				t.Errorf("decoded body = %q, want %q", got, tt.body)
+			}
+		})
+	}
+}
+
+func TestCompressBelowThresholdOnShortWriteStillFlushesOnClose(t *testing.T) {
+	handler := Compress(gzip.DefaultCompression, 1024)(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tiny"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+		t.Fatalf("Content-Encoding = %q, want none for a body under the threshold", enc)
+	}
+	if rec.Body.String() != "tiny" {
+		t.Errorf("body = %q, want %q", rec.Body.String(), "tiny")
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeartbeat(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		wantHandled   bool
+		wantNextCalls bool
+	}{
+		{"GET to the endpoint is answered directly", http.MethodGet, "/ping", true, false},
+		{"POST to the endpoint is answered regardless of method", http.MethodPost, "/ping", true, false},
+		{"other paths fall through to next", http.MethodGet, "/other", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var nextCalled bool
+			handler := Heartbeat("/ping")(func(w http.ResponseWriter, r *http.Request) {
+				nextCalled = true
+			})
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if tt.wantHandled {
+				if w.Code != http.StatusOK {
+					t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+				}
+				if w.Body.String() != "." {
+					t.Errorf("body = %q, want %q", w.Body.String(), ".")
+				}
+			}
+			if nextCalled != tt.wantNextCalls {
+				t.Errorf("next called = %v, want %v", nextCalled, tt.wantNextCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Logger returns middleware that writes a structured access log entry via
+// logger for every request, capturing status, response size and duration
+// through a WrapResponseWriter. A nil logger falls back to slog.Default().
+func Logger(logger *slog.Logger) func(http.HandlerFunc) http.HandlerFunc {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ww := WrapWriter(w)
+			start := time.Now()
+
+			next(ww, r)
+
+			logger.Info("request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", ww.Status(),
+				"bytes", ww.BytesWritten(),
+				"duration", time.Since(start),
+				"request_id", GetReqID(r.Context()),
+			)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	handler := Logger(logger)(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets", nil)
+	handler(httptest.NewRecorder(), req)
+
+	out := buf.String()
+	for _, want := range []string{`msg=request`, `method=GET`, `path=/widgets`, `status=201`, `bytes=5`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output missing %q, got: %s", want, out)
+		}
+	}
+}
+
+func TestLoggerNilFallsBackToDefault(t *testing.T) {
+	handler := Logger(nil)(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
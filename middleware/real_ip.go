@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP returns middleware that rewrites r.RemoteAddr from the
+// X-Forwarded-For or X-Real-Ip header, but only when the direct peer
+// (r.RemoteAddr) falls inside one of trustedProxies. Without that check any
+// caller could spoof another client's IP simply by setting the header, so
+// trustedProxies should list the CIDRs of your load balancers/reverse
+// proxies, not the whole internet.
+func RealIP(trustedProxies ...string) func(http.HandlerFunc) http.HandlerFunc {
+	nets := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, cidr := range trustedProxies {
+		if _, n, err := net.ParseCIDR(cidr); err == nil {
+			nets = append(nets, n)
+		}
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if isTrustedProxy(r.RemoteAddr, nets) {
+				if ip := forwardedFor(r); ip != "" {
+					r.RemoteAddr = ip
+				}
+			}
+
+			next(w, r)
+		}
+	}
+}
+
+func forwardedFor(r *http.Request) string {
+	if xrip := r.Header.Get("X-Real-Ip"); xrip != "" {
+		return xrip
+	}
+
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		first := strings.SplitN(xff, ",", 2)[0]
+		return strings.TrimSpace(first)
+	}
+
+	return ""
+}
+
+func isTrustedProxy(remoteAddr string, nets []*net.IPNet) bool {
+	if len(nets) == 0 {
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
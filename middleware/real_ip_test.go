@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		xForwardedFor  string
+		xRealIP        string
+		wantRemoteAddr string
+	}{
+		{
+			name:           "a trusted proxy's X-Forwarded-For is honored",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.1:1234",
+			xForwardedFor:  "203.0.113.5, 10.0.0.2",
+			wantRemoteAddr: "203.0.113.5",
+		},
+		{
+			name:           "an untrusted peer's header is ignored",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "198.51.100.1:1234",
+			xForwardedFor:  "203.0.113.5",
+			wantRemoteAddr: "198.51.100.1:1234",
+		},
+		{
+			name:           "X-Real-Ip takes precedence over X-Forwarded-For",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.1:1234",
+			xForwardedFor:  "203.0.113.5",
+			xRealIP:        "203.0.113.9",
+			wantRemoteAddr: "203.0.113.9",
+		},
+		{
+			name:           "no trusted proxies configured means no rewriting at all",
+			remoteAddr:     "10.0.0.1:1234",
+			xForwardedFor:  "203.0.113.5",
+			wantRemoteAddr: "10.0.0.1:1234",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRemoteAddr string
+			handler := RealIP(tt.trustedProxies...)(func(w http.ResponseWriter, r *http.Request) {
+				gotRemoteAddr = r.RemoteAddr
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.xForwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.xForwardedFor)
+			}
+			if tt.xRealIP != "" {
+				req.Header.Set("X-Real-Ip", tt.xRealIP)
+			}
+
+			handler(httptest.NewRecorder(), req)
+
+			if gotRemoteAddr != tt.wantRemoteAddr {
+				t.Errorf("RemoteAddr = %q, want %q", gotRemoteAddr, tt.wantRemoteAddr)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recoverer returns middleware that recovers panics from the rest of the
+// chain, logs the panic value and stack trace via logger, and responds 500.
+// http.ErrAbortHandler is re-panicked so net/http's own handling of client
+// disconnects is left intact. A nil logger falls back to slog.Default().
+func Recoverer(logger *slog.Logger) func(http.HandlerFunc) http.HandlerFunc {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				logger.Error("panic recovered",
+					"error", rec,
+					"stack", string(debug.Stack()),
+					"request_id", GetReqID(r.Context()),
+				)
+
+				w.WriteHeader(http.StatusInternalServerError)
+			}()
+
+			next(w, r)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the header RequestID reads an inbound request id from
+// and writes the resolved id back on, so it survives a reverse proxy hop.
+const RequestIDHeader = "X-Request-Id"
+
+type ctxKeyRequestID struct{}
+
+var requestIDKey = ctxKeyRequestID{}
+
+// RequestID is middleware that ensures every request carries a request id:
+// it reuses the inbound X-Request-Id header if present, otherwise generates
+// one, stashes it on the request context and echoes it back in the
+// response header.
+func RequestID(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := context.WithValue(r.Context(), requestIDKey, id)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// GetReqID returns the request id stashed on ctx by RequestID, or "" if none
+// is present.
+func GetReqID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name          string
+		inboundHeader string
+		wantReused    bool
+	}{
+		{"reuses an inbound request id", "inbound-id", true},
+		{"generates one when absent", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCtxID string
+			handler := RequestID(func(w http.ResponseWriter, r *http.Request) {
+				gotCtxID = GetReqID(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.inboundHeader != "" {
+				req.Header.Set(RequestIDHeader, tt.inboundHeader)
+			}
+
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if gotCtxID == "" {
+				t.Fatal("GetReqID returned empty string inside the handler")
+			}
+			if tt.wantReused && gotCtxID != tt.inboundHeader {
+				t.Errorf("request id = %q, want reused inbound id %q", gotCtxID, tt.inboundHeader)
+			}
+
+			gotHeader := w.Header().Get(RequestIDHeader)
+			if gotHeader != gotCtxID {
+				t.Errorf("response header %q = %q, want it to echo the context id %q", RequestIDHeader, gotHeader, gotCtxID)
+			}
+		})
+	}
+}
+
+func TestGetReqIDWithoutRequestIDMiddleware(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if got := GetReqID(req.Context()); got != "" {
+		t.Errorf("GetReqID = %q, want empty string", got)
+	}
+}
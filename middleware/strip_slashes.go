@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// StripSlashes is middleware that trims a single trailing slash from the
+// request path before it reaches the router, so "/users/" and "/users"
+// resolve to the same route and downstream handlers see a normalized
+// r.URL.Path.
+func StripSlashes(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(r.URL.Path) > 1 && strings.HasSuffix(r.URL.Path, "/") {
+			r.URL.Path = strings.TrimSuffix(r.URL.Path, "/")
+		}
+		next(w, r)
+	}
+}
+
+// RedirectSlashes is middleware that redirects a request whose path has a
+// trailing slash to the same path without one, preserving the query
+// string. It answers GET/HEAD with a 301 and any other method with a 308,
+// so a redirected POST keeps its method and body instead of being
+// downgraded to GET.
+func RedirectSlashes(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(r.URL.Path) > 1 && strings.HasSuffix(r.URL.Path, "/") {
+			path := strings.TrimSuffix(r.URL.Path, "/")
+			if r.URL.RawQuery != "" {
+				path += "?" + r.URL.RawQuery
+			}
+
+			status := http.StatusMovedPermanently
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				status = http.StatusPermanentRedirect
+			}
+
+			http.Redirect(w, r, path, status)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStripSlashes(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		wantPath string
+	}{
+		{"trailing slash is trimmed", "/users/", "/users"},
+		{"no trailing slash is untouched", "/users", "/users"},
+		{"bare root slash is kept", "/", "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			handler := StripSlashes(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+			})
+
+			handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if gotPath != tt.wantPath {
+				t.Errorf("path = %q, want %q", gotPath, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestRedirectSlashes(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		query      string
+		wantStatus int
+		wantLoc    string
+	}{
+		{"GET with trailing slash redirects with 301", http.MethodGet, "/users/", "", http.StatusMovedPermanently, "/users"},
+		{"POST with trailing slash redirects with 308 to preserve the method", http.MethodPost, "/users/", "", http.StatusPermanentRedirect, "/users"},
+		{"query string is preserved across the redirect", http.MethodGet, "/users/", "a=1", http.StatusMovedPermanently, "/users?a=1"},
+		{"no trailing slash passes through untouched", http.MethodGet, "/users", "", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var passedThrough bool
+			handler := RedirectSlashes(func(w http.ResponseWriter, r *http.Request) {
+				passedThrough = true
+				w.WriteHeader(http.StatusOK)
+			})
+
+			target := tt.path
+			if tt.query != "" {
+				target += "?" + tt.query
+			}
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(tt.method, target, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantLoc != "" {
+				if passedThrough {
+					t.Error("handler ran, want a redirect instead")
+				}
+				if got := w.Header().Get("Location"); got != tt.wantLoc {
+					t.Errorf("Location = %q, want %q", got, tt.wantLoc)
+				}
+			} else if !passedThrough {
+				t.Error("handler did not run, want pass-through")
+			}
+		})
+	}
+}
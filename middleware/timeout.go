@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Timeout returns middleware that caps request handling at d. It attaches a
+// context with a deadline to the request so handlers that respect ctx.Done()
+// can bail out early; if the chain is still running once d elapses, Timeout
+// writes a 503 itself (unless the handler has already written a status) and
+// returns without waiting for the handler goroutine, which keeps running in
+// the background until it finishes or gives up.
+func Timeout(d time.Duration) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			tw := &timeoutWriter{ResponseWriter: w}
+			done := make(chan struct{})
+
+			go func() {
+				next(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				if !tw.wroteHeader {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					w.Write([]byte("Service Unavailable"))
+					tw.wroteHeader = true
+				}
+				tw.finalized = true
+			}
+		}
+	}
+}
+
+// timeoutWriter guards ResponseWriter access with a mutex, since the wrapped
+// handler keeps writing from its own goroutine even after Timeout has given
+// up waiting on it and written the 503 itself. Once finalized is set, the
+// response is done and any further writes from the straggling handler are
+// discarded rather than forwarded, so they can't corrupt or append to the
+// 503 already on the wire.
+type timeoutWriter struct {
+	http.ResponseWriter
+	mu          sync.Mutex
+	wroteHeader bool
+	finalized   bool
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.wroteHeader || w.finalized {
+		return
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.finalized {
+		return len(b), nil
+	}
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.ResponseWriter.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutWritesServiceUnavailableOnDeadlineExceeded(t *testing.T) {
+	done := make(chan struct{})
+	handler := Timeout(20 * time.Millisecond)(func(w http.ResponseWriter, r *http.Request) {
+		// Sleeps well past the deadline without looking at the context, so
+		// Timeout's own select is the one that has to notice the deadline
+		// and answer 503 before this handler ever gets to write.
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("too late"))
+		close(done)
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	<-done // let the handler goroutine finish before the recorder goes out of scope
+
+	if rec.Body.String() != "Service Unavailable" {
+		t.Errorf("body = %q, want %q (straggling write after the deadline must be discarded)", rec.Body.String(), "Service Unavailable")
+	}
+}
+
+func TestTimeoutPassesThroughWhenHandlerFinishesInTime(t *testing.T) {
+	handler := Timeout(100 * time.Millisecond)(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("ok"))
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if rec.Body.String() != "ok" {
+		t.Errorf("body = %q, want %q", rec.Body.String(), "ok")
+	}
+}
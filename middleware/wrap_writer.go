@@ -0,0 +1,48 @@
+package middleware
+
+import "net/http"
+
+// WrapResponseWriter wraps an http.ResponseWriter to capture the status
+// code and byte count of the response, for middleware (such as Logger)
+// that needs to report on a response after the wrapped handler has run.
+type WrapResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+// WrapWriter wraps w so its status and byte count can be read back once the
+// handler has finished.
+func WrapWriter(w http.ResponseWriter) *WrapResponseWriter {
+	return &WrapResponseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (w *WrapResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *WrapResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Status returns the status code written to the response, or 200 if
+// WriteHeader was never called explicitly.
+func (w *WrapResponseWriter) Status() int {
+	return w.status
+}
+
+// BytesWritten returns the number of response body bytes written so far.
+func (w *WrapResponseWriter) BytesWritten() int {
+	return w.bytes
+}
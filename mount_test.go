@@ -0,0 +1,208 @@
+package chiyo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterMount(t *testing.T) {
+	sub := NewRouter()
+	if err := sub.AddRoute(http.MethodGet, "/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("sub root"))
+	}); err != nil {
+		t.Fatalf("AddRoute on sub: %v", err)
+	}
+	if err := sub.AddRoute(http.MethodGet, "/widgets/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("widget " + URLParam(r, "id")))
+	}); err != nil {
+		t.Fatalf("AddRoute on sub: %v", err)
+	}
+
+	r := NewRouter()
+	if err := r.Mount("api", sub); err != nil {
+		t.Fatalf("Mount: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{"bare mount prefix reaches sub's own root route", "/api", http.StatusOK, "sub root"},
+		{"bare mount prefix with trailing slash", "/api/", http.StatusOK, "sub root"},
+		{"path below the mount prefix", "/api/widgets/7", http.StatusOK, "widget 7"},
+		{"unrelated path stays unmatched", "/other", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestGroupMount(t *testing.T) {
+	sub := NewRouter()
+	if err := sub.AddRoute(http.MethodGet, "/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("sub root"))
+	}); err != nil {
+		t.Fatalf("AddRoute on sub: %v", err)
+	}
+
+	var mwRan bool
+	r := NewRouter()
+	g := r.Group("api")
+	g.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			mwRan = true
+			next(w, req)
+		}
+	})
+
+	if err := g.Mount("v1", sub); err != nil {
+		t.Fatalf("Mount: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w.Body.String() != "sub root" {
+		t.Errorf("body = %q, want %q", w.Body.String(), "sub root")
+	}
+	if !mwRan {
+		t.Error("group middleware did not run for a mounted route")
+	}
+}
+
+func TestGroupNestingOrderAndIsolation(t *testing.T) {
+	var order []string
+	record := func(name string) func(http.HandlerFunc) http.HandlerFunc {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, req *http.Request) {
+				order = append(order, name)
+				next(w, req)
+			}
+		}
+	}
+
+	r := NewRouter()
+	parent := r.Group("api")
+	parent.Use(record("outer"))
+
+	child := parent.Group("v1")
+	child.Use(record("inner"))
+
+	if err := child.AddRoute(http.MethodGet, "/ping", func(w http.ResponseWriter, req *http.Request) {
+		order = append(order, "handler")
+	}); err != nil {
+		t.Fatalf("AddRoute: %v", err)
+	}
+
+	// A Use call on the parent after the child was created must not
+	// retroactively reach the child's already-registered route.
+	var lateRan bool
+	parent.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			lateRan = true
+			next(w, req)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	wantOrder := []string{"outer", "inner", "handler"}
+	if len(order) != len(wantOrder) {
+		t.Fatalf("order = %v, want %v", order, wantOrder)
+	}
+	for i, name := range wantOrder {
+		if order[i] != name {
+			t.Errorf("order[%d] = %q, want %q (full order %v)", i, order[i], name, order)
+		}
+	}
+	if lateRan {
+		t.Error("middleware added to the parent group after nesting ran on the child's route")
+	}
+}
+
+func TestGroupNestingWithEmptyPrefix(t *testing.T) {
+	r := NewRouter()
+	parent := r.Group("api")
+	child := parent.Group("")
+
+	if err := child.AddRoute(http.MethodGet, "ping", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("pong"))
+	}); err != nil {
+		t.Fatalf("AddRoute: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ping", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w.Body.String() != "pong" {
+		t.Errorf("body = %q, want %q", w.Body.String(), "pong")
+	}
+}
+
+func TestRouterWith(t *testing.T) {
+	var wrapped bool
+	mw := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			wrapped = true
+			next(w, req)
+		}
+	}
+
+	r := NewRouter()
+	view := r.With(mw)
+
+	if err := view.AddRoute(http.MethodGet, "/special", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("special"))
+	}); err != nil {
+		t.Fatalf("AddRoute on view: %v", err)
+	}
+	if err := r.AddRoute(http.MethodGet, "/plain", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("plain"))
+	}); err != nil {
+		t.Fatalf("AddRoute on r: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/special", nil))
+	if w.Body.String() != "special" {
+		t.Fatalf("body = %q, want %q (route added via With should be reachable on the base router)", w.Body.String(), "special")
+	}
+	if !wrapped {
+		t.Error("middleware passed to With did not run for a route registered through the view")
+	}
+
+	wrapped = false
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/plain", nil))
+	if w.Body.String() != "plain" {
+		t.Fatalf("body = %q, want %q", w.Body.String(), "plain")
+	}
+	if wrapped {
+		t.Error("With's middleware leaked onto a route registered directly on the base router")
+	}
+}
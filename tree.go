@@ -0,0 +1,152 @@
+package chiyo
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// node is one segment of a per-method radix tree. Each node keeps three
+// ordered child buckets - static, param and catch-all - and a handler is
+// only reachable once the whole path has been consumed. Static children are
+// kept sorted by descending priority - the number of route registrations
+// that passed through each child - so the most heavily populated subtrees
+// are visited first during a search.
+type node struct {
+	segment string
+	handler http.HandlerFunc
+	pattern string
+
+	statics []*node
+
+	param     *node
+	paramName string
+
+	catchAll     *node
+	catchAllName string
+
+	priority int
+}
+
+// insert walks parts into the tree rooted at n, creating nodes as needed.
+// pattern is the full route template as registered (e.g. "users/:id"),
+// stashed on the terminal node so a later search can report what matched
+// instead of the literal request path. insert returns an error instead of
+// overwriting an existing registration when two inserts disagree on the
+// param name at the same position or both try to register a handler for
+// the same path.
+func (n *node) insert(parts []string, pattern string, handler http.HandlerFunc) error {
+	n.priority++
+
+	if len(parts) == 0 {
+		if n.handler != nil {
+			return fmt.Errorf("chiyo: duplicate route for this path and method")
+		}
+		n.handler = handler
+		n.pattern = pattern
+		return nil
+	}
+
+	part := parts[0]
+	rest := parts[1:]
+
+	switch {
+	case strings.HasPrefix(part, ":"):
+		name := strings.TrimPrefix(part, ":")
+		if n.param == nil {
+			n.param = &node{paramName: name}
+		} else if n.param.paramName != name {
+			return fmt.Errorf("chiyo: conflicting param names %q and %q at the same position", n.param.paramName, name)
+		}
+		return n.param.insert(rest, pattern, handler)
+
+	case strings.HasPrefix(part, "*"):
+		if len(rest) != 0 {
+			return fmt.Errorf("chiyo: catch-all %q must be the last path segment", part)
+		}
+		name := strings.TrimPrefix(part, "*")
+		if name == "" {
+			name = "*"
+		}
+		if n.catchAll == nil {
+			n.catchAll = &node{catchAllName: name}
+		} else if n.catchAll.catchAllName != name {
+			return fmt.Errorf("chiyo: conflicting catch-all names %q and %q at the same position", n.catchAll.catchAllName, name)
+		}
+		if n.catchAll.handler != nil {
+			return fmt.Errorf("chiyo: duplicate route for this path and method")
+		}
+		n.catchAll.handler = handler
+		n.catchAll.pattern = pattern
+		return nil
+
+	default:
+		child := n.staticChild(part)
+		if child == nil {
+			child = &node{segment: part}
+			n.statics = append(n.statics, child)
+		}
+		if err := child.insert(rest, pattern, handler); err != nil {
+			return err
+		}
+		n.sortStatics()
+		return nil
+	}
+}
+
+func (n *node) staticChild(segment string) *node {
+	for _, child := range n.statics {
+		if child.segment == segment {
+			return child
+		}
+	}
+	return nil
+}
+
+func (n *node) sortStatics() {
+	sort.SliceStable(n.statics, func(i, j int) bool {
+		return n.statics[i].priority > n.statics[j].priority
+	})
+}
+
+// search walks parts against the tree rooted at n and reports the node of
+// the first full match, backtracking from static children to the param
+// child to the catch-all child whenever a deeper branch turns out to be a
+// dead end. The returned node's pattern is the registered route template,
+// not the literal path that was searched for.
+func (n *node) search(parts []string, params *routeParams) *node {
+	if len(parts) == 0 {
+		if n.handler == nil {
+			return nil
+		}
+		return n
+	}
+
+	part := parts[0]
+	rest := parts[1:]
+
+	if child := n.staticChild(part); child != nil {
+		if m := child.search(rest, params); m != nil {
+			return m
+		}
+	}
+
+	if n.param != nil {
+		mark, extraMark := params.mark()
+		params.add(n.param.paramName, part)
+
+		if m := n.param.search(rest, params); m != nil {
+			return m
+		}
+
+		params.truncate(mark, extraMark)
+	}
+
+	if n.catchAll != nil && n.catchAll.handler != nil {
+		params.add(n.catchAll.catchAllName, strings.Join(parts, "/"))
+		return n.catchAll
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package chiyo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func mustInsert(t *testing.T, root *node, pattern string, handler http.HandlerFunc) {
+	t.Helper()
+	if err := root.insert(strings.Split(pattern, "/"), pattern, handler); err != nil {
+		t.Fatalf("insert(%q): %v", pattern, err)
+	}
+}
+
+func marker(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", name)
+	}
+}
+
+func TestNodeInsertAndSearch(t *testing.T) {
+	root := &node{}
+	mustInsert(t, root, "users/new", marker("new"))
+	mustInsert(t, root, "users/:id", marker("byID"))
+	mustInsert(t, root, "files/*filepath", marker("files"))
+
+	tests := []struct {
+		path        string
+		wantHandler string
+		wantPattern string
+		wantParams  map[string]string
+	}{
+		{"users/new", "new", "users/new", nil},
+		{"users/42", "byID", "users/:id", map[string]string{"id": "42"}},
+		{"files/a/b/c.txt", "files", "files/*filepath", map[string]string{"filepath": "a/b/c.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var params routeParams
+			m := root.search(strings.Split(tt.path, "/"), &params)
+			if m == nil {
+				t.Fatalf("search(%q) = nil, want a match", tt.path)
+			}
+
+			w := httptest.NewRecorder()
+			m.handler(w, httptest.NewRequest(http.MethodGet, "/"+tt.path, nil))
+
+			if got := w.Header().Get("X-Handler"); got != tt.wantHandler {
+				t.Errorf("handler = %q, want %q", got, tt.wantHandler)
+			}
+			if m.pattern != tt.wantPattern {
+				t.Errorf("pattern = %q, want %q", m.pattern, tt.wantPattern)
+			}
+			for key, want := range tt.wantParams {
+				if got := params.get(key); got != want {
+					t.Errorf("param %q = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNodeSearchPrefersStaticOverParam(t *testing.T) {
+	root := &node{}
+	mustInsert(t, root, "users/:id", marker("byID"))
+	mustInsert(t, root, "users/new", marker("new"))
+
+	var params routeParams
+	m := root.search(strings.Split("users/new", "/"), &params)
+	if m == nil {
+		t.Fatal("search(\"users/new\") = nil, want a match")
+	}
+
+	w := httptest.NewRecorder()
+	m.handler(w, httptest.NewRequest(http.MethodGet, "/users/new", nil))
+	if got := w.Header().Get("X-Handler"); got != "new" {
+		t.Errorf("handler = %q, want %q (static should win over the param sibling)", got, "new")
+	}
+}
+
+func TestNodeInsertConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		inserts []string
+	}{
+		{"conflicting param names at the same position", []string{"users/:id", "users/:slug"}},
+		{"duplicate static route", []string{"users/new", "users/new"}},
+		{"duplicate catch-all route", []string{"files/*path", "files/*path"}},
+		{"conflicting catch-all names", []string{"files/*path", "files/*name"}},
+	}
+
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &node{}
+			var lastErr error
+			for _, pattern := range tt.inserts {
+				lastErr = root.insert(strings.Split(pattern, "/"), pattern, noop)
+			}
+			if lastErr == nil {
+				t.Fatalf("insert sequence %v: want an error on the final insert, got nil", tt.inserts)
+			}
+		})
+	}
+}
+
+func TestNodeStaticPriorityOrdering(t *testing.T) {
+	root := &node{}
+	mustInsert(t, root, "a/x", marker("ax"))
+	mustInsert(t, root, "a/y", marker("ay"))
+	mustInsert(t, root, "b", marker("b"))
+
+	if len(root.statics) != 2 {
+		t.Fatalf("len(root.statics) = %d, want 2", len(root.statics))
+	}
+	if root.statics[0].segment != "a" {
+		t.Errorf("statics[0].segment = %q, want %q (visited twice, should sort first)", root.statics[0].segment, "a")
+	}
+	if root.statics[1].segment != "b" {
+		t.Errorf("statics[1].segment = %q, want %q", root.statics[1].segment, "b")
+	}
+}